@@ -1,29 +1,70 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"log"
 	"net/http"
-	"sync"
+	"strconv"
 
+	"go-echo/app/orderstore"
 	"go-echo/app/rabbitmq"
+	"go-echo/pkg/messaging"
 
 	"github.com/labstack/echo/v4"
 )
 
-// Order represents the structure of an order with fields for ID, item, price, and message type.
-type Order struct {
-	ID          string `json:"id"`
-	Item        string `json:"item"`
-	Price       int    `json:"price"`
-	MessageType string `json:"message_type"`
+// Order is the request/response representation of an order; it's an alias
+// for orderstore.Order so the HTTP layer and the event-sourced store agree
+// on wire shape without a conversion step.
+type Order = orderstore.Order
+
+// broker is the messaging transport OrderHandler publishes order events
+// through, selected at startup from the BROKER env var. It must be set via
+// Init before the routes are registered.
+var broker messaging.Broker
+
+// store is the read model OrderHandler serves reads from. It is only ever
+// mutated by the consumer applying events off the broker, never directly
+// by a handler, so the broker stays the source of truth.
+var store orderstore.Store
+
+// Init wires the Broker and Store that the order handlers depend on. It
+// must be called once during startup, before the Echo routes are
+// registered.
+func Init(b messaging.Broker, s orderstore.Store) {
+	broker = b
+	store = s
 }
 
-var orders = make(map[string]*Order)
-var mu sync.RWMutex
+// deadLetters is the shared DLQ consumer backing GetDeadOrdersHandler and
+// RequeueDeadOrderHandler. It's a RabbitMQ-specific concept with no NATS
+// equivalent wired up, so it's nil (and those endpoints respond
+// accordingly) unless InitDeadLetters was called.
+var deadLetters *rabbitmq.DeadLetterConsumer
 
-// OrderHandler receives HTTP requests and sends messages to RabbitMQ
-// This function handles incoming HTTP requests, binds the request body to an Order struct,
-// connects to RabbitMQ, publishes a message, and returns an appropriate HTTP response.
+// InitDeadLetters wires the DeadLetterConsumer that /orders/dead reads from.
+func InitDeadLetters(d *rabbitmq.DeadLetterConsumer) {
+	deadLetters = d
+}
+
+// publishOrderEvent marshals order and publishes it on the topic derived
+// from order.MessageType, waiting for the broker's confirm. order.ID is
+// passed through as the message id so a transport's durable retry
+// bookkeeping (e.g. RabbitMQBroker's on-disk retry store) can key on it
+// consistently across restarts.
+func publishOrderEvent(ctx context.Context, order *Order) error {
+	body, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	return broker.Publish(ctx, order.ID, "order."+order.MessageType, body)
+}
+
+// OrderHandler receives HTTP requests and publishes an order.created event.
+// It only responds once the broker has durably accepted the message; the
+// order itself only becomes visible to reads once the consumer applies the
+// event to the store.
 func OrderHandler(c echo.Context) error {
 	// Create a new Order instance
 	order := new(Order)
@@ -36,42 +77,21 @@ func OrderHandler(c echo.Context) error {
 			"error": "Invalid request body",
 		})
 	}
+	order.MessageType = "created"
 
-	// Save order to the global map
-	mu.Lock()
-	orders[order.ID] = order
-	mu.Unlock()
-
-	// Connect to RabbitMQ
-	// This function returns a channel and a connection to RabbitMQ
-	ch, conn, err := rabbitmq.ConnectRabbitMQ()
-	if err != nil {
-		// Log an error if connection fails and return a 500 Internal Server Error response
-		log.Printf("Error connecting to RabbitMQ: %v", err)
-		return c.JSON(http.StatusInternalServerError, echo.Map{
-			"error": "OrderHandler failed to connect to RabbitMQ",
-		})
-	}
-	// Ensure the connection and channel are closed when the function exits
-	defer conn.Close()
-	defer ch.Close()
-
-	// Publish the message to RabbitMQ
-	// The message type (e.g., order.created, order.updated) determines the routing of the message
-	err = rabbitmq.PublishMessage(ch, order.ID, order.MessageType)
-	if err != nil {
-		// Log an error if publishing fails and return a 500 Internal Server Error response
-		log.Printf("Error publishing message to RabbitMQ: %v", err)
+	if err := publishOrderEvent(c.Request().Context(), order); err != nil {
+		// Log an error if the broker never durably accepted the message and return a 500 Internal Server Error response
+		log.Printf("Error publishing order event: %v", err)
 		return c.JSON(http.StatusInternalServerError, echo.Map{
-			"error": "Failed to send message to RabbitMQ",
+			"error": "Failed to publish order event",
 		})
 	}
 
 	// Log the successful receipt and queuing of the order
 	log.Printf("Order received and queued: ID=%s, MessageType=%s", order.ID, order.MessageType)
 
-	// Return a 200 OK response with the order details
-	return c.JSON(http.StatusOK, echo.Map{
+	// Return a 202 Accepted response now that the broker has durably accepted the message
+	return c.JSON(http.StatusAccepted, echo.Map{
 		"message":     "Order received and queued",
 		"orderID":     order.ID,
 		"messageType": order.MessageType,
@@ -79,18 +99,27 @@ func OrderHandler(c echo.Context) error {
 }
 
 func GetAllOrdersHandler(c echo.Context) error {
-	mu.Lock()
-	defer mu.Unlock()
+	list, err := store.List(c.Request().Context())
+	if err != nil {
+		log.Printf("Error listing orders: %v", err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Failed to list orders",
+		})
+	}
 
-	return c.JSON(http.StatusOK, orders)
+	return c.JSON(http.StatusOK, list)
 }
 
 func GetOrderHandler(c echo.Context) error {
-	mu.Lock()
-	defer mu.Unlock()
-
 	id := c.Param("id")
-	order, ok := orders[id]
+
+	order, ok, err := store.Get(c.Request().Context(), id)
+	if err != nil {
+		log.Printf("Error getting order %s: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Failed to get order",
+		})
+	}
 	if !ok {
 		return c.JSON(http.StatusNotFound, echo.Map{
 			"error": "Order not found",
@@ -100,12 +129,19 @@ func GetOrderHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, order)
 }
 
+// UpdateOrderHandler publishes an order.updated event for an existing
+// order; the store only reflects it once the consumer applies the event.
 func UpdateOrderHandler(c echo.Context) error {
-	mu.Lock()
-	defer mu.Unlock()
-
 	id := c.Param("id")
-	order, ok := orders[id]
+	ctx := c.Request().Context()
+
+	order, ok, err := store.Get(ctx, id)
+	if err != nil {
+		log.Printf("Error getting order %s: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Failed to get order",
+		})
+	}
 	if !ok {
 		return c.JSON(http.StatusNotFound, echo.Map{
 			"error": "Order not found",
@@ -122,23 +158,97 @@ func UpdateOrderHandler(c echo.Context) error {
 
 	order.Item = newOrder.Item
 	order.Price = newOrder.Price
+	order.MessageType = "updated"
 
-	return c.JSON(http.StatusOK, order)
+	if err := publishOrderEvent(ctx, order); err != nil {
+		log.Printf("Error publishing order event: %v", err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Failed to publish order event",
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, order)
 }
 
-func DeleteOrderHandler(c echo.Context) error {
-	mu.Lock()
-	defer mu.Unlock()
+// GetDeadOrdersHandler lists the poison messages currently parked in the
+// orders_dlq so operators can inspect what permanently failed processing.
+func GetDeadOrdersHandler(c echo.Context) error {
+	if deadLetters == nil {
+		return c.JSON(http.StatusNotImplemented, echo.Map{
+			"error": "Dead-letter inspection is only available with BROKER=rabbitmq",
+		})
+	}
+
+	msgs := deadLetters.List()
+
+	out := make([]echo.Map, 0, len(msgs))
+	for _, msg := range msgs {
+		out = append(out, echo.Map{
+			"deliveryTag": msg.DeliveryTag,
+			"messageId":   msg.MessageId,
+			"body":        string(msg.Body),
+		})
+	}
+
+	return c.JSON(http.StatusOK, out)
+}
+
+// RequeueDeadOrderHandler re-publishes a poison message, identified by its
+// delivery tag, back onto the orders queue for reprocessing.
+func RequeueDeadOrderHandler(c echo.Context) error {
+	if deadLetters == nil {
+		return c.JSON(http.StatusNotImplemented, echo.Map{
+			"error": "Dead-letter inspection is only available with BROKER=rabbitmq",
+		})
+	}
+
+	tag, err := strconv.ParseUint(c.Param("tag"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Invalid delivery tag",
+		})
+	}
+
+	if err := deadLetters.Requeue(c.Request().Context(), tag); err != nil {
+		log.Printf("Error requeueing dead-lettered message: %v", err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Failed to requeue message",
+		})
+	}
 
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteOrderHandler publishes an order.deleted event for an existing
+// order; the store only reflects the deletion once the consumer applies
+// the event.
+func DeleteOrderHandler(c echo.Context) error {
 	id := c.Param("id")
-	_, ok := orders[id]
+	ctx := c.Request().Context()
+
+	order, ok, err := store.Get(ctx, id)
+	if err != nil {
+		log.Printf("Error getting order %s: %v", id, err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Failed to get order",
+		})
+	}
 	if !ok {
 		return c.JSON(http.StatusNotFound, echo.Map{
 			"error": "Order not found",
 		})
 	}
 
-	delete(orders, id)
+	order.MessageType = "deleted"
+	if err := publishOrderEvent(ctx, order); err != nil {
+		log.Printf("Error publishing order event: %v", err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Failed to publish order event",
+		})
+	}
 
-	return c.NoContent(http.StatusNoContent)
+	return c.JSON(http.StatusAccepted, echo.Map{
+		"message": "Order deletion queued",
+		"orderID": id,
+	})
 }
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-echo/app/orderstore"
+	"go-echo/pkg/messaging"
+	"go-echo/pkg/messaging/mock"
+
+	"github.com/labstack/echo/v4"
+)
+
+// memStore is a minimal in-memory orderstore.Store for tests, so they don't
+// need a real database/sql driver.
+type memStore struct {
+	mu     sync.Mutex
+	orders map[string]*orderstore.Order
+}
+
+func newMemStore() *memStore {
+	return &memStore{orders: make(map[string]*orderstore.Order)}
+}
+
+func (s *memStore) Get(ctx context.Context, id string) (*orderstore.Order, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[id]
+	return o, ok, nil
+}
+
+func (s *memStore) List(ctx context.Context) ([]*orderstore.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*orderstore.Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+func (s *memStore) Apply(ctx context.Context, ev orderstore.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch ev.Type {
+	case orderstore.EventCreated, orderstore.EventUpdated:
+		o := ev.Order
+		s.orders[o.ID] = &o
+	case orderstore.EventDeleted:
+		delete(s.orders, ev.Order.ID)
+	}
+	return nil
+}
+
+func newTestHandler(t *testing.T) (*mock.Broker, *memStore) {
+	t.Helper()
+	broker := mock.New()
+	store := newMemStore()
+
+	// Wire the mock broker's deliveries straight into the store, the way
+	// the consumer would in production, so OrderHandler's publish-then-read
+	// round trip can be exercised without a real broker.
+	if _, err := broker.Subscribe(context.Background(), "order.created", func(ctx context.Context, msg messaging.Message) error {
+		return orderstore.ApplyMessage(ctx, store, msg.Topic, msg.Body)
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	Init(broker, store)
+	return broker, store
+}
+
+func TestOrderHandlerPublishesAndStoresOrder(t *testing.T) {
+	_, store := newTestHandler(t)
+
+	body := strings.NewReader(`{"id":"order-1","item":"widget","price":100}`)
+	req := httptest.NewRequest(http.MethodPost, "/orders", body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+
+	if err := OrderHandler(c); err != nil {
+		t.Fatalf("OrderHandler returned error: %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	order, ok, err := store.Get(context.Background(), "order-1")
+	if err != nil {
+		t.Fatalf("unexpected error getting order: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected order to be applied to the store")
+	}
+	if order.Item != "widget" || order.Price != 100 {
+		t.Fatalf("unexpected order contents: %+v", order)
+	}
+}
+
+func TestOrderHandlerRejectsInvalidBody(t *testing.T) {
+	newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`not json`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+
+	if err := OrderHandler(c); err != nil {
+		t.Fatalf("OrderHandler returned error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestGetOrderHandlerNotFound(t *testing.T) {
+	newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/missing", nil)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("missing")
+
+	if err := GetOrderHandler(c); err != nil {
+		t.Fatalf("GetOrderHandler returned error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
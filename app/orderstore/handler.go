@@ -0,0 +1,45 @@
+package orderstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go-echo/app/rabbitmq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrMalformed marks a message body that couldn't be decoded as an Order.
+// It's never going to parse on retry either, so callers should treat it as
+// a permanent failure rather than requeuing it.
+var ErrMalformed = errors.New("malformed order payload")
+
+// ApplyMessage decodes body as an Order and applies it to store under
+// eventType (order.created/order.updated/order.deleted). It's the shared
+// core of NewHandler, and is also used directly by brokers (e.g. NATS) that
+// hand callers a plain topic/body pair instead of an amqp.Delivery.
+func ApplyMessage(ctx context.Context, store Store, eventType string, body []byte) error {
+	var order Order
+	if err := json.Unmarshal(body, &order); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return store.Apply(ctx, Event{Type: eventType, Order: order})
+}
+
+// NewHandler adapts Store to a rabbitmq.Handler: each delivery's routing
+// key (order.created/order.updated/order.deleted) becomes the Event's
+// Type, and its body is the Order to apply. Malformed payloads are
+// permanent failures so they're routed straight to the DLQ instead of
+// being retried forever; any other failure (e.g. a transient store error)
+// is left to OrdersConsumer's normal retry handling.
+func NewHandler(store Store) rabbitmq.Handler {
+	return func(ctx context.Context, d amqp.Delivery) error {
+		err := ApplyMessage(ctx, store, d.RoutingKey, d.Body)
+		if errors.Is(err, ErrMalformed) {
+			return rabbitmq.Permanent(err)
+		}
+		return err
+	}
+}
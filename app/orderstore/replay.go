@@ -0,0 +1,107 @@
+package orderstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"go-echo/app/rabbitmq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Bootstrap declares the orders.history queue — bound to order_topic with
+// routing key "order.*", and deliberately with no x-message-ttl so every
+// event is retained — and replays whatever has accumulated there into
+// store, rebuilding it from scratch. Call this once at startup, before the
+// live consumers start, so reads reflect history as of boot.
+func Bootstrap(ctx context.Context, connector *rabbitmq.Connector, store Store) error {
+	ch, err := connector.AcquireChannel(ctx)
+	if err != nil {
+		return fmt.Errorf("orderstore: failed to acquire channel for replay: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(
+		"order_topic", // name
+		"topic",       // type
+		true,          // durable
+		false,         // auto-deleted
+		false,         // internal
+		false,         // no-wait
+		nil,           // arguments
+	); err != nil {
+		return fmt.Errorf("orderstore: failed to declare order_topic: %w", err)
+	}
+
+	if _, err := ch.QueueDeclare(
+		"orders.history", // name: durable history of every order event ever published
+		true,             // durable
+		false,            // delete when unused
+		false,            // exclusive
+		false,            // no-wait
+		nil,              // arguments: no x-message-ttl, events are kept indefinitely
+	); err != nil {
+		return fmt.Errorf("orderstore: failed to declare orders.history: %w", err)
+	}
+
+	if err := ch.QueueBind("orders.history", "order.*", "order_topic", false, nil); err != nil {
+		return fmt.Errorf("orderstore: failed to bind orders.history: %w", err)
+	}
+
+	// orders.history is a plain queue, not a replayable log, so Bootstrap
+	// must never Ack its way through it — that would permanently drain the
+	// one copy of history a later rebuild (e.g. after orders.db is lost)
+	// depends on. RabbitMQ doesn't preserve FIFO order across a
+	// Nack(requeue=true) on a classic queue — a nack'd message commonly
+	// comes straight back on the very next Get — so nacking one entry at a
+	// time while still reading the rest would risk re-reading the same
+	// handful of messages instead of making a single pass over all of
+	// history. Instead, drain every delivery for the queue's depth at
+	// start into memory first, without resolving any of them, and only
+	// Nack(requeue=true) them — putting the queue back exactly as found —
+	// once the full read pass, and the store writes it drives, are done.
+	// Bootstrap is always the only reader (it runs once at startup, before
+	// any live consumer starts), so there's no one else to race for
+	// redelivery in the meantime.
+	q, err := ch.QueueInspect("orders.history")
+	if err != nil {
+		return fmt.Errorf("orderstore: failed to inspect orders.history: %w", err)
+	}
+
+	deliveries := make([]amqp.Delivery, 0, q.Messages)
+	for i := 0; i < q.Messages; i++ {
+		msg, ok, err := ch.Get("orders.history", false)
+		if err != nil {
+			return fmt.Errorf("orderstore: failed to read orders.history: %w", err)
+		}
+		if !ok {
+			break
+		}
+		deliveries = append(deliveries, msg)
+	}
+
+	for _, msg := range deliveries {
+		var order Order
+		if err := json.Unmarshal(msg.Body, &order); err != nil {
+			// Never going to parse differently on a future replay either,
+			// so this one entry is a permanent loss rather than the whole
+			// queue's.
+			log.Printf("orderstore: dropping unreadable history entry: %v", err)
+			if ackErr := msg.Ack(false); ackErr != nil {
+				return fmt.Errorf("orderstore: failed to ack unreadable history entry: %w", ackErr)
+			}
+			continue
+		}
+
+		if err := store.Apply(ctx, Event{Type: msg.RoutingKey, Order: order}); err != nil {
+			return err
+		}
+		if err := msg.Nack(false, true); err != nil {
+			return fmt.Errorf("orderstore: failed to requeue history entry: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+// Package orderstore holds the read model for orders. It is mutated only
+// by applying events consumed off the order_topic exchange, so the broker
+// (not the HTTP layer) is the source of truth for order state.
+package orderstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Order is the read-model representation of an order.
+type Order struct {
+	ID          string `json:"id"`
+	Item        string `json:"item"`
+	Price       int    `json:"price"`
+	MessageType string `json:"message_type"`
+}
+
+// Event is an event-sourced mutation applied to the Store. Type matches the
+// routing key it arrived on (e.g. "order.created", "order.updated",
+// "order.deleted").
+type Event struct {
+	Type  string
+	Order Order
+}
+
+const (
+	EventCreated = "order.created"
+	EventUpdated = "order.updated"
+	EventDeleted = "order.deleted"
+)
+
+// Store is the read/write surface the handlers and consumer depend on.
+// OrderHandler only calls Get/List; Apply is only called by the consumer
+// that applies events off the broker.
+type Store interface {
+	Get(ctx context.Context, id string) (*Order, bool, error)
+	List(ctx context.Context) ([]*Order, error)
+	Apply(ctx context.Context, ev Event) error
+}
+
+// SQLStore is the default Store, backed by a database/sql connection.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// Open creates the orders table on db if it doesn't already exist and
+// returns a ready-to-use Store.
+func Open(ctx context.Context, db *sql.DB) (*SQLStore, error) {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS orders (
+			id           TEXT PRIMARY KEY,
+			item         TEXT NOT NULL,
+			price        INTEGER NOT NULL,
+			message_type TEXT NOT NULL
+		)
+	`); err != nil {
+		return nil, fmt.Errorf("orderstore: failed to create orders table: %w", err)
+	}
+	return &SQLStore{db: db}, nil
+}
+
+// Get returns the order with id, or ok=false if it doesn't exist.
+func (s *SQLStore) Get(ctx context.Context, id string) (*Order, bool, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, item, price, message_type FROM orders WHERE id = ?`, id)
+
+	var o Order
+	if err := row.Scan(&o.ID, &o.Item, &o.Price, &o.MessageType); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("orderstore: failed to get order %s: %w", id, err)
+	}
+	return &o, true, nil
+}
+
+// List returns every order currently in the store.
+func (s *SQLStore) List(ctx context.Context) ([]*Order, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, item, price, message_type FROM orders`)
+	if err != nil {
+		return nil, fmt.Errorf("orderstore: failed to list orders: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Order{}
+	for rows.Next() {
+		var o Order
+		if err := rows.Scan(&o.ID, &o.Item, &o.Price, &o.MessageType); err != nil {
+			return nil, fmt.Errorf("orderstore: failed to scan order: %w", err)
+		}
+		out = append(out, &o)
+	}
+	return out, rows.Err()
+}
+
+// Apply mutates the store according to ev.Type, upserting on
+// order.created/order.updated and deleting on order.deleted.
+func (s *SQLStore) Apply(ctx context.Context, ev Event) error {
+	switch ev.Type {
+	case EventCreated, EventUpdated:
+		if _, err := s.db.ExecContext(ctx, `
+			INSERT INTO orders (id, item, price, message_type) VALUES (?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET item = excluded.item, price = excluded.price, message_type = excluded.message_type
+		`, ev.Order.ID, ev.Order.Item, ev.Order.Price, ev.Order.MessageType); err != nil {
+			return fmt.Errorf("orderstore: failed to apply %s for %s: %w", ev.Type, ev.Order.ID, err)
+		}
+	case EventDeleted:
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM orders WHERE id = ?`, ev.Order.ID); err != nil {
+			return fmt.Errorf("orderstore: failed to apply order.deleted for %s: %w", ev.Order.ID, err)
+		}
+	default:
+		return fmt.Errorf("orderstore: unknown event type %q", ev.Type)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// EventType identifies the kind of lifecycle event emitted by a Connector.
+type EventType int
+
+const (
+	// ConnectionFailed is emitted whenever the underlying AMQP connection
+	// drops, whether from a broker restart or a network partition.
+	ConnectionFailed EventType = iota
+	// Reconnected is emitted once a redial succeeds.
+	Reconnected
+	// ConsumerClosed is emitted whenever a StartConsumer loop stops
+	// consuming, either because its channel closed or Consume returned.
+	ConsumerClosed
+)
+
+// Event is published on a Connector's Events channel so callers can log or
+// expose metrics without polling connection state.
+type Event struct {
+	Type EventType
+	Err  error
+}
+
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+)
+
+// Connector owns a single long-lived AMQP connection, redialing it with
+// bounded exponential backoff and jitter whenever the broker drops it, and
+// hands out channels to callers via AcquireChannel. It replaces the old
+// pattern of dialing a fresh connection per HTTP request or consumer.
+type Connector struct {
+	url    string
+	events chan Event
+
+	mu   sync.RWMutex
+	conn *amqp.Connection
+}
+
+// NewConnector dials url and starts the background redial loop. The
+// returned Connector is ready to hand out channels once the initial dial
+// succeeds; a failed initial dial is returned as an error rather than
+// retried, since the caller is usually still in its own startup path.
+func NewConnector(ctx context.Context, url string) (*Connector, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("connector: initial dial failed: %w", err)
+	}
+
+	c := &Connector{
+		url:    url,
+		events: make(chan Event, 16),
+		conn:   conn,
+	}
+
+	go c.watch(ctx, conn)
+
+	return c, nil
+}
+
+// Events returns the channel on which connection lifecycle events are
+// published. Sends are non-blocking once the buffer is full, so a slow or
+// absent reader never stalls reconnection.
+func (c *Connector) Events() <-chan Event {
+	return c.events
+}
+
+func (c *Connector) emit(ev Event) {
+	select {
+	case c.events <- ev:
+	default:
+	}
+}
+
+// watch blocks until conn closes, then redials with backoff+jitter until a
+// new connection is established or ctx is cancelled.
+func (c *Connector) watch(ctx context.Context, conn *amqp.Connection) {
+	closeCh := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	select {
+	case err := <-closeCh:
+		c.emit(Event{Type: ConnectionFailed, Err: errOrClosed(err)})
+	case <-ctx.Done():
+		return
+	}
+
+	backoff := initialBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		next, err := amqp.Dial(c.url)
+		if err != nil {
+			wait := withJitter(backoff)
+			log.Printf("rabbitmq: redial failed, retrying in %s: %v", wait, err)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+
+		c.mu.Lock()
+		c.conn = next
+		c.mu.Unlock()
+		c.emit(Event{Type: Reconnected})
+
+		backoff = initialBackoff
+		closeCh = next.NotifyClose(make(chan *amqp.Error, 1))
+		select {
+		case err := <-closeCh:
+			c.emit(Event{Type: ConnectionFailed, Err: errOrClosed(err)})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// nextBackoff doubles d, capping at maxBackoff so a long outage doesn't grow
+// the redial interval without bound.
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+// withJitter adds a random extra delay in [0, d) on top of d, so a fleet of
+// Connectors that all lost the broker at once don't all redial in lockstep.
+func withJitter(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Int63n(int64(d)))
+}
+
+func errOrClosed(err *amqp.Error) error {
+	if err == nil {
+		return errors.New("connection closed")
+	}
+	return err
+}
+
+// AcquireChannel returns a fresh channel on the current connection. Callers
+// own the returned channel and must close it when done; channels are not
+// pooled or multiplexed beyond sharing the underlying connection.
+func (c *Connector) AcquireChannel(ctx context.Context) (*amqp.Channel, error) {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	if conn == nil {
+		return nil, errors.New("connector: not connected")
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("connector: failed to open channel: %w", err)
+	}
+	return ch, nil
+}
+
+// Consumer is implemented by callers of StartConsumer. Declare is invoked on
+// every (re)connect so exchanges, queues and bindings are re-asserted, and
+// Consume is invoked in a loop to process deliveries until the channel or
+// context closes.
+type Consumer interface {
+	Declare(ctx context.Context, ch *amqp.Channel) error
+	Consume(ctx context.Context, ch *amqp.Channel) error
+}
+
+// StartConsumer runs c against the Connector until ctx is cancelled,
+// re-declaring topology and resuming consumption after every broker outage
+// instead of leaking goroutines or killing the process.
+func (c *Connector) StartConsumer(ctx context.Context, consumer Consumer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		ch, err := c.AcquireChannel(ctx)
+		if err != nil {
+			c.emit(Event{Type: ConsumerClosed, Err: err})
+			if !sleep(ctx, initialBackoff) {
+				return
+			}
+			continue
+		}
+
+		if err := consumer.Declare(ctx, ch); err != nil {
+			log.Printf("rabbitmq: consumer declare failed: %v", err)
+			ch.Close()
+			c.emit(Event{Type: ConsumerClosed, Err: err})
+			if !sleep(ctx, initialBackoff) {
+				return
+			}
+			continue
+		}
+
+		err = consumer.Consume(ctx, ch)
+		ch.Close()
+		c.emit(Event{Type: ConsumerClosed, Err: err})
+		if err != nil {
+			log.Printf("rabbitmq: consumer stopped: %v", err)
+		}
+
+		if !sleep(ctx, initialBackoff) {
+			return
+		}
+	}
+}
+
+// Close shuts down the current connection, giving in-flight operations up
+// to 5 seconds to finish before forcing the socket closed, so a graceful
+// shutdown doesn't hang forever on a wedged broker.
+func (c *Connector) Close() error {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.CloseDeadline(time.Now().Add(5 * time.Second))
+}
+
+// StartMultipleConsumers spawns n independent StartConsumer goroutines
+// sharing the same Consumer, so a slow handler can be scaled out across
+// multiple channels without duplicating reconnect logic.
+func (c *Connector) StartMultipleConsumers(ctx context.Context, consumer Consumer, n int) {
+	for i := 0; i < n; i++ {
+		go c.StartConsumer(ctx, consumer)
+	}
+}
+
+// sleep waits for d or ctx cancellation, returning false if ctx was
+// cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
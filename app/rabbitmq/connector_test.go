@@ -0,0 +1,38 @@
+package rabbitmq
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBackoff(t *testing.T) {
+	tests := []struct {
+		name string
+		in   time.Duration
+		want time.Duration
+	}{
+		{"doubles from initial", initialBackoff, initialBackoff * 2},
+		{"doubles mid-range", 4 * time.Second, 8 * time.Second},
+		{"caps at maxBackoff when doubling would exceed it", 20 * time.Second, maxBackoff},
+		{"stays capped once already at maxBackoff", maxBackoff, maxBackoff},
+		{"caps when doubling lands exactly on maxBackoff", maxBackoff / 2, maxBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextBackoff(tt.in); got != tt.want {
+				t.Errorf("nextBackoff(%s) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	d := 2 * time.Second
+	for i := 0; i < 100; i++ {
+		got := withJitter(d)
+		if got < d || got >= 2*d {
+			t.Fatalf("withJitter(%s) = %s, want in [%s, %s)", d, got, d, 2*d)
+		}
+	}
+}
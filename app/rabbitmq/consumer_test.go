@@ -0,0 +1,50 @@
+package rabbitmq
+
+import (
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestRetryCount(t *testing.T) {
+	tests := []struct {
+		name string
+		d    amqp.Delivery
+		want int
+	}{
+		{"nil headers default to 0", amqp.Delivery{}, 0},
+		{"missing header defaults to 0", amqp.Delivery{Headers: amqp.Table{}}, 0},
+		{"int32 header", amqp.Delivery{Headers: amqp.Table{retryCountHeader: int32(3)}}, 3},
+		{"int64 header", amqp.Delivery{Headers: amqp.Table{retryCountHeader: int64(4)}}, 4},
+		{"int header", amqp.Delivery{Headers: amqp.Table{retryCountHeader: 5}}, 5},
+		{"unrecognized type defaults to 0", amqp.Delivery{Headers: amqp.Table{retryCountHeader: "3"}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryCount(tt.d); got != tt.want {
+				t.Errorf("retryCount(%+v) = %d, want %d", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryHeaders(t *testing.T) {
+	original := amqp.Table{"x-custom": "keep-me"}
+
+	got := retryHeaders(original, 2)
+
+	if got[retryCountHeader] != int32(2) {
+		t.Errorf("retryHeaders: x-retry-count = %v, want int32(2)", got[retryCountHeader])
+	}
+	if got["x-custom"] != "keep-me" {
+		t.Errorf("retryHeaders: x-custom = %v, want %q", got["x-custom"], "keep-me")
+	}
+	if _, ok := original[retryCountHeader]; ok {
+		t.Errorf("retryHeaders mutated the caller's original headers table")
+	}
+
+	if got := retryHeaders(nil, 0); got[retryCountHeader] != int32(0) {
+		t.Errorf("retryHeaders(nil, 0): x-retry-count = %v, want int32(0)", got[retryCountHeader])
+	}
+}
@@ -0,0 +1,134 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetterConsumer drains the orders_dlq queue into memory so operators
+// can inspect poison messages and requeue them back onto the orders queue
+// via the handlers package's /orders/dead endpoints.
+type DeadLetterConsumer struct {
+	mu   sync.RWMutex
+	ch   *amqp.Channel
+	msgs map[uint64]amqp.Delivery
+}
+
+// NewDeadLetterConsumer returns a Consumer bound to the orders_dlq queue.
+func NewDeadLetterConsumer() *DeadLetterConsumer {
+	return &DeadLetterConsumer{msgs: make(map[uint64]amqp.Delivery)}
+}
+
+// Declare asserts the orders_dlq queue exists. It doesn't own the queue's
+// definition (OrdersConsumer.Declare does), so it only declares, never
+// deletes or redefines it.
+func (d *DeadLetterConsumer) Declare(ctx context.Context, ch *amqp.Channel) error {
+	if _, err := ch.QueueDeclare(
+		"orders_dlq", // DLQ name
+		true,         // durable
+		false,        // delete when unused
+		false,        // exclusive
+		false,        // no-wait
+		nil,          // arguments
+	); err != nil {
+		return fmt.Errorf("failed to declare the DLQ: %w", err)
+	}
+	return nil
+}
+
+// Consume reads poison messages from orders_dlq, leaving them unacked and
+// held in memory until List/Requeue resolves them.
+func (d *DeadLetterConsumer) Consume(ctx context.Context, ch *amqp.Channel) error {
+	d.mu.Lock()
+	d.ch = ch
+	// DeliveryTag is only unique within the channel that delivered it.
+	// Entries from a previous channel can't be Ack'd or Requeue'd through
+	// this new one (their tags may not even mean the same thing here), and
+	// the broker already considers them undelivered again once the old
+	// channel closed, so drop them rather than risk matching a stale
+	// Delivery against the wrong channel.
+	d.msgs = make(map[uint64]amqp.Delivery)
+	d.mu.Unlock()
+
+	msgs, err := ch.ConsumeWithContext(
+		ctx,
+		"orders_dlq", // name of the queue
+		"",           // consumer tag
+		false,        // auto-ack: left unacked until an operator requeues or clears it
+		false,        // exclusive
+		false,        // no-local
+		false,        // no-wait
+		nil,          // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register the DLQ consumer: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-msgs:
+			if !ok {
+				return nil
+			}
+			d.mu.Lock()
+			d.msgs[msg.DeliveryTag] = msg
+			d.mu.Unlock()
+		}
+	}
+}
+
+// List returns the poison messages currently parked for inspection.
+func (d *DeadLetterConsumer) List() []amqp.Delivery {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	out := make([]amqp.Delivery, 0, len(d.msgs))
+	for _, msg := range d.msgs {
+		out = append(out, msg)
+	}
+	return out
+}
+
+// Requeue re-publishes the poison message identified by deliveryTag
+// directly onto the orders queue (via the default exchange, so the
+// original routing key doesn't matter) and acks it out of the DLQ. ctx is
+// the requesting HTTP call's context, so a client disconnect aborts the
+// publish instead of leaking it.
+func (d *DeadLetterConsumer) Requeue(ctx context.Context, deliveryTag uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	msg, ok := d.msgs[deliveryTag]
+	if !ok {
+		return fmt.Errorf("rabbitmq: no dead-lettered message with tag %d", deliveryTag)
+	}
+
+	if err := d.ch.PublishWithContext(
+		ctx,
+		"",       // default exchange
+		"orders", // route straight to the orders queue by name
+		false,    // mandatory
+		false,    // immediate
+		amqp.Publishing{
+			ContentType:  msg.ContentType,
+			DeliveryMode: msg.DeliveryMode,
+			MessageId:    msg.MessageId,
+			Timestamp:    msg.Timestamp,
+			Body:         msg.Body,
+		},
+	); err != nil {
+		return fmt.Errorf("rabbitmq: failed to requeue message %s: %w", msg.MessageId, err)
+	}
+
+	if err := msg.Ack(false); err != nil {
+		return fmt.Errorf("rabbitmq: failed to ack dead-lettered message %s: %w", msg.MessageId, err)
+	}
+
+	delete(d.msgs, deliveryTag)
+	return nil
+}
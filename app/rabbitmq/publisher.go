@@ -0,0 +1,260 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	bolt "go.etcd.io/bbolt"
+)
+
+// retryBucket is the bbolt bucket that durably holds publishes the broker
+// has not yet acked, so they survive a process restart until replayLoop can
+// resend them.
+var retryBucket = []byte("pending_publishes")
+
+// pendingPublish is the on-disk representation of a message queued for
+// retry, keyed by MessageId in the bbolt store.
+type pendingPublish struct {
+	Exchange   string `json:"exchange"`
+	RoutingKey string `json:"routing_key"`
+	Body       []byte `json:"body"`
+}
+
+// Publisher wraps a Connector channel in confirm mode so Publish only
+// returns once the broker has durably accepted the message. Anything the
+// broker nacks, returns as unroutable, or can't be sent because the channel
+// is down is persisted to an on-disk retry store and replayed once the
+// Connector reports Reconnected.
+type Publisher struct {
+	connector *Connector
+	store     *bolt.DB
+
+	// mu serializes Publish/PublishRoute, replayPending and openChannel, so
+	// a publish's wait on acks/rets can never be matched against a
+	// different in-flight publish's confirmation, and a reconnect can never
+	// swap ch/acks/rets out from under a publish that's using them.
+	mu   sync.Mutex
+	ch   *amqp.Channel
+	acks chan amqp.Confirmation
+	rets chan amqp.Return
+}
+
+// NewPublisher puts a channel from connector into confirm mode and opens
+// (creating if needed) the bbolt retry store at dbPath. It starts a
+// background worker that replays queued messages whenever connector
+// reports a Reconnected event.
+func NewPublisher(ctx context.Context, connector *Connector, dbPath string) (*Publisher, error) {
+	store, err := bolt.Open(dbPath, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("publisher: failed to open retry store: %w", err)
+	}
+	if err := store.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(retryBucket)
+		return err
+	}); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("publisher: failed to init retry store: %w", err)
+	}
+
+	p := &Publisher{connector: connector, store: store}
+	if err := p.openChannel(ctx); err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	go p.replayLoop(ctx)
+
+	return p, nil
+}
+
+// openChannel acquires a fresh channel from the Connector, puts it into
+// confirm mode, and (re)registers the publish/return notifications Publish
+// waits on.
+func (p *Publisher) openChannel(ctx context.Context) error {
+	ch, err := p.connector.AcquireChannel(ctx)
+	if err != nil {
+		return fmt.Errorf("publisher: failed to acquire channel: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return fmt.Errorf("publisher: failed to enable confirm mode: %w", err)
+	}
+
+	p.mu.Lock()
+	p.ch = ch
+	p.acks = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	p.rets = ch.NotifyReturn(make(chan amqp.Return, 1))
+	p.mu.Unlock()
+	return nil
+}
+
+// Publish sends body on the order_topic exchange with a routing key derived
+// from messageType, marking it persistent and mandatory, and blocks until
+// the broker confirms receipt. On nack, unroutable return, or a publish
+// error it enqueues the message in the retry store and returns an error so
+// callers know the broker has not durably accepted it.
+func (p *Publisher) Publish(ctx context.Context, id string, messageType string, body []byte) error {
+	return p.PublishRoute(ctx, id, "order_topic", "order."+messageType, body)
+}
+
+// PublishRoute is Publish with an explicit exchange and routing key instead
+// of the order_topic exchange and a routing key derived from an order
+// message type, for callers (such as messaging.RabbitMQBroker) that address
+// exchanges/topics directly; whatever's passed here is also what gets
+// replayed from the retry store, so it's never assumed to be order_topic.
+func (p *Publisher) PublishRoute(ctx context.Context, id string, exchange string, routingKey string, body []byte) error {
+	pub := amqp.Publishing{
+		ContentType:  "text/plain",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    id,
+		Timestamp:    time.Now(),
+		Body:         body,
+	}
+
+	// Hold mu for the full publish+confirm round trip: acks/rets are
+	// shared, unbuffered-in-practice notification channels on the current
+	// channel, so a second concurrent publish could otherwise consume this
+	// one's confirmation (or vice versa), and a reconnect could swap the
+	// channel out from under us mid-wait.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.ch.PublishWithContext(
+		ctx,
+		exchange,   // exchange
+		routingKey, // routing key
+		true,       // mandatory
+		false,      // immediate
+		pub,
+	); err != nil {
+		p.enqueueRetry(id, exchange, routingKey, body)
+		return fmt.Errorf("publisher: failed to publish: %w", err)
+	}
+
+	select {
+	case ret := <-p.rets:
+		p.enqueueRetry(id, exchange, routingKey, body)
+		return fmt.Errorf("publisher: message %s returned as unroutable: %s", id, ret.ReplyText)
+	case confirm := <-p.acks:
+		if !confirm.Ack {
+			p.enqueueRetry(id, exchange, routingKey, body)
+			return fmt.Errorf("publisher: broker nacked message %s", id)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	log.Printf("Message sent: %s with routing key: %s", id, routingKey)
+	return nil
+}
+
+// enqueueRetry persists a message the broker has not durably accepted so it
+// survives a restart, keyed by id so a later successful replay can be
+// deleted idempotently.
+func (p *Publisher) enqueueRetry(id, exchange, routingKey string, body []byte) {
+	data, err := json.Marshal(pendingPublish{Exchange: exchange, RoutingKey: routingKey, Body: body})
+	if err != nil {
+		log.Printf("publisher: failed to encode retry entry for %s: %v", id, err)
+		return
+	}
+	if err := p.store.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(retryBucket).Put([]byte(id), data)
+	}); err != nil {
+		log.Printf("publisher: failed to persist retry entry for %s: %v", id, err)
+	}
+}
+
+// replayLoop reopens the publish channel and drains the retry store every
+// time the Connector reports a successful reconnect.
+func (p *Publisher) replayLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-p.connector.Events():
+			if !ok {
+				return
+			}
+			if ev.Type != Reconnected {
+				continue
+			}
+			if err := p.openChannel(ctx); err != nil {
+				log.Printf("publisher: failed to reopen channel after reconnect: %v", err)
+				continue
+			}
+			p.replayPending(ctx)
+		}
+	}
+}
+
+// replayPending resends every message in the retry store, deleting each one
+// once the broker has re-acked it.
+func (p *Publisher) replayPending(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var pending map[string]pendingPublish
+	if err := p.store.View(func(tx *bolt.Tx) error {
+		pending = make(map[string]pendingPublish)
+		return tx.Bucket(retryBucket).ForEach(func(k, v []byte) error {
+			var entry pendingPublish
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			pending[string(k)] = entry
+			return nil
+		})
+	}); err != nil {
+		log.Printf("publisher: failed to read retry store: %v", err)
+		return
+	}
+
+	for id, entry := range pending {
+		if err := p.ch.PublishWithContext(
+			ctx,
+			entry.Exchange,
+			entry.RoutingKey,
+			true,
+			false,
+			amqp.Publishing{
+				ContentType:  "text/plain",
+				DeliveryMode: amqp.Persistent,
+				MessageId:    id,
+				Timestamp:    time.Now(),
+				Body:         entry.Body,
+			},
+		); err != nil {
+			log.Printf("publisher: retry publish failed for %s: %v", id, err)
+			continue
+		}
+
+		confirm := <-p.acks
+		if !confirm.Ack {
+			log.Printf("publisher: retry publish nacked for %s", id)
+			continue
+		}
+
+		if err := p.store.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(retryBucket).Delete([]byte(id))
+		}); err != nil {
+			log.Printf("publisher: failed to clear retry entry for %s: %v", id, err)
+		}
+	}
+}
+
+// Close releases the publisher's channel and retry store.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	ch := p.ch
+	p.mu.Unlock()
+
+	if ch != nil {
+		ch.Close()
+	}
+	return p.store.Close()
+}
@@ -0,0 +1,34 @@
+// Package messaging decouples business logic from a specific message
+// transport, so handlers and main.go depend only on Broker and are
+// selected at startup from the BROKER environment variable.
+package messaging
+
+import "context"
+
+// Message is the transport-agnostic envelope handed to a Subscribe
+// handler — just a topic-scoped payload, no AMQP/NATS wire types leaking
+// through.
+type Message struct {
+	Topic string
+	Body  []byte
+}
+
+// Subscription is returned by Broker.Subscribe so callers can stop
+// receiving messages for a topic.
+type Subscription interface {
+	Unsubscribe() error
+}
+
+// Broker is implemented by every message transport this module supports:
+// rabbitmq (the default, amqp091-go-backed), nats, and mock (in-memory, for
+// unit tests).
+type Broker interface {
+	// Publish sends msg on topic. id identifies the message to the
+	// transport's own durability/retry bookkeeping (e.g. RabbitMQBroker's
+	// on-disk retry store), so callers must pass a caller-assigned id that
+	// is stable across retries and process restarts, such as the domain
+	// entity's own ID — never a value derived from in-memory state.
+	Publish(ctx context.Context, id string, topic string, msg []byte) error
+	Subscribe(ctx context.Context, topic string, handler func(ctx context.Context, msg Message) error) (Subscription, error)
+	Close() error
+}
@@ -0,0 +1,116 @@
+// Package mock provides an in-memory messaging.Broker for unit tests, so
+// handlers depending on messaging.Broker can be covered by httptest
+// without dialing a real broker.
+package mock
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go-echo/pkg/messaging"
+)
+
+// Broker is an in-memory messaging.Broker. Publish records the message on
+// Messages and invokes any handlers Subscribed to the topic synchronously,
+// so tests don't need a goroutine/sleep dance to observe delivery.
+type Broker struct {
+	mu       sync.Mutex
+	Messages []messaging.Message
+	subs     map[string]map[int]func(ctx context.Context, msg messaging.Message) error
+	nextID   int
+}
+
+// New returns an empty Broker.
+func New() *Broker {
+	return &Broker{subs: make(map[string]map[int]func(ctx context.Context, msg messaging.Message) error)}
+}
+
+// Publish records msg and synchronously invokes every handler whose
+// subscribed pattern matches topic, returning the first error any of them
+// report. id is unused: the mock keeps no on-disk retry bookkeeping to key
+// on.
+func (b *Broker) Publish(ctx context.Context, id string, topic string, msg []byte) error {
+	b.mu.Lock()
+	b.Messages = append(b.Messages, messaging.Message{Topic: topic, Body: msg})
+	var handlers []func(ctx context.Context, msg messaging.Message) error
+	for pattern, subs := range b.subs {
+		if !topicMatches(pattern, topic) {
+			continue
+		}
+		for _, h := range subs {
+			handlers = append(handlers, h)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, messaging.Message{Topic: topic, Body: msg}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe registers handler for topic, which may be an exact topic or an
+// AMQP-style pattern using "*" to match exactly one dot-delimited word and
+// "#" to match zero or more, the same as RabbitMQBroker's topic exchange
+// and compatible with the single-level "*" wildcard NATSBroker supports
+// natively, so subscribing to e.g. "order.*" behaves like it would against
+// either real driver.
+func (b *Broker) Subscribe(ctx context.Context, topic string, handler func(ctx context.Context, msg messaging.Message) error) (messaging.Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subs[topic] == nil {
+		b.subs[topic] = make(map[int]func(ctx context.Context, msg messaging.Message) error)
+	}
+	id := b.nextID
+	b.nextID++
+	b.subs[topic][id] = handler
+
+	return &subscription{broker: b, topic: topic, id: id}, nil
+}
+
+// topicMatches reports whether topic satisfies pattern, using AMQP topic
+// exchange matching: "*" stands for exactly one "."-delimited word and "#"
+// for zero or more.
+func topicMatches(pattern, topic string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(topic, "."))
+}
+
+func matchWords(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		if matchWords(pattern[1:], words) {
+			return true
+		}
+		return len(words) > 0 && matchWords(pattern, words[1:])
+	case "*":
+		return len(words) > 0 && matchWords(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && matchWords(pattern[1:], words[1:])
+	}
+}
+
+// Close is a no-op.
+func (b *Broker) Close() error {
+	return nil
+}
+
+type subscription struct {
+	broker *Broker
+	topic  string
+	id     int
+}
+
+func (s *subscription) Unsubscribe() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	delete(s.broker.subs[s.topic], s.id)
+	return nil
+}
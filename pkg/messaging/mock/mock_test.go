@@ -0,0 +1,51 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"go-echo/pkg/messaging"
+)
+
+func TestBrokerSubscribeWildcard(t *testing.T) {
+	b := New()
+
+	var got []string
+	if _, err := b.Subscribe(context.Background(), "order.*", func(ctx context.Context, msg messaging.Message) error {
+		got = append(got, msg.Topic)
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	if err := b.Publish(context.Background(), "order-1", "order.created", []byte("{}")); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+	if err := b.Publish(context.Background(), "order-1", "order.updated.extra", []byte("{}")); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	if len(got) != 1 || got[0] != "order.created" {
+		t.Fatalf("expected exactly one delivery of order.created, got %v", got)
+	}
+}
+
+func TestBrokerSubscribeExactTopic(t *testing.T) {
+	b := New()
+
+	var delivered bool
+	if _, err := b.Subscribe(context.Background(), "order.created", func(ctx context.Context, msg messaging.Message) error {
+		delivered = true
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	if err := b.Publish(context.Background(), "order-1", "order.updated", []byte("{}")); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	if delivered {
+		t.Fatal("handler for order.created should not receive an order.updated message")
+	}
+}
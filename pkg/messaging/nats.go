@@ -0,0 +1,61 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSBroker implements Broker on top of a nats.Conn. It has no DLQ or
+// persistent-retry story of its own (plain NATS core pub/sub doesn't
+// either); callers that need those guarantees should use RabbitMQBroker.
+type NATSBroker struct {
+	conn *nats.Conn
+}
+
+// NewNATSBroker connects to url, reconnecting indefinitely on disconnect.
+func NewNATSBroker(url string) (*NATSBroker, error) {
+	conn, err := nats.Connect(url, nats.RetryOnFailedConnect(true), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("messaging: failed to connect to NATS: %w", err)
+	}
+	return &NATSBroker{conn: conn}, nil
+}
+
+// Publish sends msg as the body of a NATS message on subject topic. id is
+// unused: plain NATS core pub/sub has no delivery bookkeeping to key on.
+func (b *NATSBroker) Publish(ctx context.Context, id string, topic string, msg []byte) error {
+	if err := b.conn.Publish(topic, msg); err != nil {
+		return fmt.Errorf("messaging: failed to publish to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Subscribe invokes handler for every message received on topic.
+func (b *NATSBroker) Subscribe(ctx context.Context, topic string, handler func(ctx context.Context, msg Message) error) (Subscription, error) {
+	sub, err := b.conn.Subscribe(topic, func(m *nats.Msg) {
+		if err := handler(ctx, Message{Topic: m.Subject, Body: m.Data}); err != nil {
+			log.Printf("messaging: handler error for topic %s: %v", topic, err)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("messaging: failed to subscribe to %s: %w", topic, err)
+	}
+	return &natsSubscription{sub: sub}, nil
+}
+
+// Close drains and closes the underlying connection.
+func (b *NATSBroker) Close() error {
+	b.conn.Close()
+	return nil
+}
+
+type natsSubscription struct {
+	sub *nats.Subscription
+}
+
+func (s *natsSubscription) Unsubscribe() error {
+	return s.sub.Unsubscribe()
+}
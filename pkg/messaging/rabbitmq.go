@@ -0,0 +1,117 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go-echo/app/rabbitmq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RabbitMQBroker implements Broker on top of app/rabbitmq's reconnecting
+// Connector and confirm-mode Publisher, so Publish keeps the durability and
+// retry guarantees those already provide. It publishes and subscribes on a
+// single topic exchange, using the topic string directly as the routing
+// key.
+type RabbitMQBroker struct {
+	connector *rabbitmq.Connector
+	publisher *rabbitmq.Publisher
+	exchange  string
+}
+
+// NewRabbitMQBroker wraps an already-connected Connector and Publisher
+// (typically the ones main.go also uses for the orders pipeline) as a
+// Broker addressing exchange.
+func NewRabbitMQBroker(connector *rabbitmq.Connector, publisher *rabbitmq.Publisher, exchange string) *RabbitMQBroker {
+	return &RabbitMQBroker{connector: connector, publisher: publisher, exchange: exchange}
+}
+
+// Publish sends msg on topic, waiting for the broker's confirm. id must be
+// the caller's own stable identifier (e.g. an order ID), since it becomes
+// both the AMQP MessageId and the retry store's key: a synthetic
+// in-memory counter would reset across restarts and collide with whatever
+// the on-disk retry store already has pending. It's namespaced by topic so
+// the same id publishing distinct events (e.g. order.created then
+// order.updated for the same order) doesn't overwrite a still-pending
+// retry entry for the other.
+func (b *RabbitMQBroker) Publish(ctx context.Context, id string, topic string, msg []byte) error {
+	return b.publisher.PublishRoute(ctx, fmt.Sprintf("%s:%s", id, topic), b.exchange, topic, msg)
+}
+
+// Subscribe binds a fresh exclusive queue to exchange/topic on every
+// (re)connect and invokes handler for each delivery, auto-acking since
+// Broker makes no DLQ/retry guarantees beyond what handler itself does.
+func (b *RabbitMQBroker) Subscribe(ctx context.Context, topic string, handler func(ctx context.Context, msg Message) error) (Subscription, error) {
+	subCtx, cancel := context.WithCancel(ctx)
+
+	consumer := &topicConsumer{exchange: b.exchange, topic: topic, handler: handler}
+	go b.connector.StartConsumer(subCtx, consumer)
+
+	return &rabbitmqSubscription{cancel: cancel}, nil
+}
+
+// Close releases the Publisher's channel and retry store, then closes the
+// underlying Connector's connection with a deadline so any consumers still
+// sharing it (e.g. the DLQ consumer) see their channels close cleanly
+// instead of hanging on a half-shutdown broker.
+func (b *RabbitMQBroker) Close() error {
+	pubErr := b.publisher.Close()
+	connErr := b.connector.Close()
+	if pubErr != nil {
+		return pubErr
+	}
+	return connErr
+}
+
+type rabbitmqSubscription struct {
+	cancel context.CancelFunc
+}
+
+func (s *rabbitmqSubscription) Unsubscribe() error {
+	s.cancel()
+	return nil
+}
+
+// topicConsumer adapts a Broker handler func to rabbitmq.Consumer, binding
+// a fresh exclusive queue to exchange/topic on every (re)connect.
+type topicConsumer struct {
+	exchange  string
+	topic     string
+	handler   func(ctx context.Context, msg Message) error
+	queueName string
+}
+
+func (c *topicConsumer) Declare(ctx context.Context, ch *amqp.Channel) error {
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return fmt.Errorf("messaging: failed to declare queue for topic %s: %w", c.topic, err)
+	}
+	if err := ch.QueueBind(q.Name, c.topic, c.exchange, false, nil); err != nil {
+		return fmt.Errorf("messaging: failed to bind queue for topic %s: %w", c.topic, err)
+	}
+	c.queueName = q.Name
+	return nil
+}
+
+func (c *topicConsumer) Consume(ctx context.Context, ch *amqp.Channel) error {
+	msgs, err := ch.ConsumeWithContext(ctx, c.queueName, "", true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("messaging: failed to consume topic %s: %w", c.topic, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-msgs:
+			if !ok {
+				return nil
+			}
+			if err := c.handler(ctx, Message{Topic: d.RoutingKey, Body: d.Body}); err != nil {
+				log.Printf("messaging: handler error for topic %s: %v", c.topic, err)
+			}
+		}
+	}
+}